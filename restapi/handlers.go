@@ -0,0 +1,31 @@
+// This file is part of MinIO Console Server
+// Copyright (c) 2020 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package restapi
+
+import "github.com/minio/console/restapi/operations"
+
+// registerHandlers is the single place ConfigureAPI calls into to wire every restapi handler
+// registration function onto the generated swagger API. Adding a new registerXHandlers function
+// without listing it here leaves its endpoints unreachable, so new handler files must add
+// themselves to this list as part of the same change.
+func registerHandlers(api *operations.ConsoleAPI) {
+	registerBucketsHandlers(api)
+	registerBucketPolicyHandlers(api)
+	registerBucketConfigHandlers(api)
+	registerBulkBucketHandlers(api)
+	registerBucketSelectHandlers(api)
+}
@@ -0,0 +1,67 @@
+// This file is part of MinIO Console Server
+// Copyright (c) 2020 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package restapi
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/minio/console/restapi/operations"
+)
+
+// ConfigureAPI is the composition root the generated swagger server calls during startup: it
+// wires every handler registration function onto api before any request can be served.
+func ConfigureAPI(api *operations.ConsoleAPI) {
+	registerHandlers(api)
+}
+
+// bulkProgressPathPrefix is the mount point for the bulk-operation progress websocket. It sits
+// outside the generated swagger operations, which only model request/response JSON calls, so it
+// is matched against the raw request path ahead of the swagger-generated handler.
+const bulkProgressPathPrefix = "/ws/bulk/"
+
+// WrapHandler intercepts requests under bulkProgressPathPrefix and serves them as a websocket via
+// serveBulkJobProgress, delegating every other request to the generated swagger handler. Because
+// this path is matched before the generated handler ever runs, it skips the security middleware
+// that populates session on every operation in operations.ConsoleAPI, so it must authenticate the
+// request itself the same way the trace/log websocket endpoints do.
+func WrapHandler(generated http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if jobID, ok := trimBulkProgressPrefix(r.URL.Path); ok {
+			session, err := getClaimsFromTokenInRequest(r)
+			if err != nil {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+			serveBulkJobProgress(w, r, jobID, session)
+			return
+		}
+		generated.ServeHTTP(w, r)
+	})
+}
+
+// trimBulkProgressPrefix extracts the jobID from a /ws/bulk/{jobID} request path.
+func trimBulkProgressPrefix(path string) (string, bool) {
+	if !strings.HasPrefix(path, bulkProgressPathPrefix) {
+		return "", false
+	}
+	jobID := strings.TrimPrefix(path, bulkProgressPathPrefix)
+	if jobID == "" {
+		return "", false
+	}
+	return jobID, true
+}
@@ -21,6 +21,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"sort"
 	"strings"
 	"time"
 
@@ -37,7 +38,7 @@ import (
 func registerBucketsHandlers(api *operations.ConsoleAPI) {
 	// list buckets
 	api.UserAPIListBucketsHandler = user_api.ListBucketsHandlerFunc(func(params user_api.ListBucketsParams, session *models.Principal) middleware.Responder {
-		listBucketsResponse, err := getListBucketsResponse(session)
+		listBucketsResponse, err := getListBucketsResponse(session, params)
 		if err != nil {
 			return user_api.NewListBucketsDefault(500).WithPayload(&models.Error{Code: 500, Message: swag.String(err.Error())})
 		}
@@ -85,14 +86,23 @@ func getaAcountUsageInfo(ctx context.Context, client MinioAdmin) ([]*models.Buck
 	}
 	var bucketInfos []*models.Bucket
 	for _, bucket := range info.Buckets {
-		bucketElem := &models.Bucket{Name: swag.String(bucket.Name), CreationDate: bucket.Created.String(), Size: int64(bucket.Size)}
+		bucketElem := &models.Bucket{
+			Name:          swag.String(bucket.Name),
+			CreationDate:  bucket.Created.String(),
+			Size:          int64(bucket.Size),
+			ObjectsCount:  int64(bucket.ObjectsCount),
+			VersionsCount: int64(bucket.VersionsCount),
+		}
 		bucketInfos = append(bucketInfos, bucketElem)
 	}
 	return bucketInfos, nil
 }
 
-// getListBucketsResponse performs listBuckets() and serializes it to the handler's output
-func getListBucketsResponse(session *models.Principal) (*models.ListBucketsResponse, error) {
+// getListBucketsResponse performs listBuckets() and serializes it to the handler's output.
+// It supports offset/limit pagination, name filtering and sorting by name, size or creation
+// date, deferring to the cached account usage info so repeated pages do not re-hit the admin
+// API on every request.
+func getListBucketsResponse(session *models.Principal, params user_api.ListBucketsParams) (*models.ListBucketsResponse, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*20)
 	defer cancel()
 
@@ -104,20 +114,85 @@ func getListBucketsResponse(session *models.Principal) (*models.ListBucketsRespo
 	// create a minioClient interface implementation
 	// defining the client to be used
 	adminClient := adminClient{client: mAdmin}
-	buckets, err := getaAcountUsageInfo(ctx, adminClient)
+	cachedBuckets, totalUsage, err := getCachedAccountUsageInfo(ctx, adminClient, session)
 	if err != nil {
 		log.Println("error accountingUsageInfo:", err)
 		return nil, err
 	}
+	// cachedBuckets holds the pointers stored in accountUsageInfoCache: clone each one before any
+	// filtering, sorting or zeroing below so this request can't mutate what other requests (or
+	// getBucketInfoResponse) read from the same cache entry during its TTL.
+	buckets := make([]*models.Bucket, len(cachedBuckets))
+	for i, bucket := range cachedBuckets {
+		bucketCopy := *bucket
+		buckets[i] = &bucketCopy
+	}
+
+	if nameContains := swag.StringValue(params.NameContains); nameContains != "" {
+		filtered := make([]*models.Bucket, 0, len(buckets))
+		for _, bucket := range buckets {
+			if strings.Contains(swag.StringValue(bucket.Name), nameContains) {
+				filtered = append(filtered, bucket)
+			}
+		}
+		buckets = filtered
+	}
+
+	sortBucketsBy(buckets, swag.StringValue(params.SortBy))
+
+	total := int64(len(buckets))
+	offset := swag.Int64Value(params.Offset)
+	limit := swag.Int64Value(params.Limit)
+	buckets = paginateBuckets(buckets, offset, limit)
+
+	if !swag.BoolValue(params.WithUsage) {
+		for _, bucket := range buckets {
+			bucket.Size = 0
+			bucket.ObjectsCount = 0
+			bucket.VersionsCount = 0
+		}
+	}
 
 	// serialize output
 	listBucketsResponse := &models.ListBucketsResponse{
-		Buckets: buckets,
-		Total:   int64(len(buckets)),
+		Buckets:    buckets,
+		Total:      total,
+		TotalUsage: totalUsage,
 	}
 	return listBucketsResponse, nil
 }
 
+// sortBucketsBy orders buckets in place by name, size or creation date; name is the default and
+// the fallback for an unrecognized sortBy value.
+func sortBucketsBy(buckets []*models.Bucket, sortBy string) {
+	switch sortBy {
+	case "size":
+		sort.Slice(buckets, func(i, j int) bool { return buckets[i].Size < buckets[j].Size })
+	case "created":
+		sort.Slice(buckets, func(i, j int) bool { return buckets[i].CreationDate < buckets[j].CreationDate })
+	default:
+		sort.Slice(buckets, func(i, j int) bool {
+			return swag.StringValue(buckets[i].Name) < swag.StringValue(buckets[j].Name)
+		})
+	}
+}
+
+// paginateBuckets slices buckets to the requested offset/limit window, clamping to the available
+// range. A non-positive limit returns every bucket from offset onwards.
+func paginateBuckets(buckets []*models.Bucket, offset, limit int64) []*models.Bucket {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= int64(len(buckets)) {
+		return []*models.Bucket{}
+	}
+	end := int64(len(buckets))
+	if limit > 0 && offset+limit < end {
+		end = offset + limit
+	}
+	return buckets[offset:end]
+}
+
 // makeBucket creates a bucket for an specific minio client
 func makeBucket(ctx context.Context, client MinioClient, bucketName string) error {
 	// creates a new bucket with bucketName with a context to control cancellations and timeouts.
@@ -241,6 +316,8 @@ func getBucketInfo(client MinioClient, bucketName string) (*models.Bucket, error
 		return nil, err
 	}
 	var policyAccess policy.BucketPolicy
+	var statementCount int64
+	var hasPrefixRules bool
 	if policyStr == "" {
 		policyAccess = policy.BucketPolicyNone
 	} else {
@@ -249,20 +326,43 @@ func getBucketInfo(client MinioClient, bucketName string) (*models.Bucket, error
 			return nil, err
 		}
 		policyAccess = policy.GetPolicy(p.Statements, bucketName, "")
+		statementCount = int64(len(p.Statements))
+		hasPrefixRules = bucketPolicyHasPrefixRules(p, bucketName)
 	}
 	bucketAccess := policyAccess2consoleAccess(policyAccess)
 	if bucketAccess == models.BucketAccessPRIVATE && policyStr != "" {
 		bucketAccess = models.BucketAccessCUSTOM
 	}
 	bucket := &models.Bucket{
-		Name:         &bucketName,
-		Access:       bucketAccess,
-		CreationDate: "", // to be implemented
-		Size:         0,  // to be implemented
+		Name:            &bucketName,
+		Access:          bucketAccess,
+		CreationDate:    "", // to be implemented
+		Size:            0,  // to be implemented
+		StatementsCount: statementCount,
+		HasPrefixRules:  hasPrefixRules,
 	}
 	return bucket, nil
 }
 
+// bucketPolicyHasPrefixRules reports whether p contains at least one statement whose resource is
+// scoped to an object prefix rather than the whole bucket, so the console can tell "CUSTOM" that
+// grants blanket access apart from "CUSTOM" built out of per-prefix statements.
+func bucketPolicyHasPrefixRules(p policy.BucketAccessPolicy, bucketName string) bool {
+	bucketResource := fmt.Sprintf("arn:aws:s3:::%s", bucketName)
+	blanketResource := bucketResource + "/*"
+	for _, statement := range p.Statements {
+		for _, resource := range statement.Resources.ToSlice() {
+			if resource == blanketResource {
+				continue
+			}
+			if strings.HasPrefix(resource, bucketResource+"/") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // getBucketInfoResponse calls getBucketInfo() to get the bucket's info
 func getBucketInfoResponse(session *models.Principal, params user_api.BucketInfoParams) (*models.Bucket, error) {
 	mClient, err := newMinioClient(session)
@@ -279,6 +379,29 @@ func getBucketInfoResponse(session *models.Principal, params user_api.BucketInfo
 		log.Println("error getting bucket's info:", err)
 		return nil, err
 	}
+
+	mAdmin, err := newMAdminClient(session)
+	if err != nil {
+		log.Println("error creating Madmin Client:", err)
+		return nil, err
+	}
+	adminClient := adminClient{client: mAdmin}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*20)
+	defer cancel()
+	bucket.ConfigSummary = bucketConfigSummary(ctx, mClient, adminClient, params.Name)
+
+	if usageBuckets, _, err := getCachedAccountUsageInfo(ctx, adminClient, session); err == nil {
+		for _, usageBucket := range usageBuckets {
+			if swag.StringValue(usageBucket.Name) == params.Name {
+				bucket.CreationDate = usageBucket.CreationDate
+				bucket.Size = usageBucket.Size
+				bucket.ObjectsCount = usageBucket.ObjectsCount
+				bucket.VersionsCount = usageBucket.VersionsCount
+				break
+			}
+		}
+	}
+
 	return bucket, nil
 
 }
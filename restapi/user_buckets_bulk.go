@@ -0,0 +1,372 @@
+// This file is part of MinIO Console Server
+// Copyright (c) 2020 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package restapi
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/swag"
+	"github.com/gorilla/websocket"
+	"github.com/minio/console/models"
+	"github.com/minio/console/restapi/operations"
+	"github.com/minio/console/restapi/operations/user_api"
+)
+
+// defaultBulkWorkerCount is the number of buckets processed concurrently by the bulk handlers
+// when the caller does not request a specific worker count.
+const defaultBulkWorkerCount = 8
+
+// bulkBucketEvent is a single {bucket, status, error} progress update, both returned inline for
+// small batches and streamed over the /ws/bulk/{jobID} websocket endpoint.
+type bulkBucketEvent struct {
+	Bucket string `json:"bucket"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// bulkJobTTL bounds how long a completed (or abandoned) job's events and jobID stay resident in
+// bulkJobs; without it the map and its event histories grow without bound across the life of the
+// process on a console handling a steady stream of bulk operations.
+const bulkJobTTL = 15 * time.Minute
+
+// bulkJob tracks the in-flight progress of a bulk operation so the websocket endpoint can
+// subscribe to events even if it connects slightly after the job started.
+type bulkJob struct {
+	mu        sync.Mutex
+	events    []bulkBucketEvent
+	done      bool
+	subs      []chan bulkBucketEvent
+	owner     string // session.AccessKeyID of the request that created this job
+	createdAt time.Time
+}
+
+func (j *bulkJob) publish(evt bulkBucketEvent) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.events = append(j.events, evt)
+	for _, sub := range j.subs {
+		// Non-blocking: a full buffer means a stalled websocket reader, and this event is
+		// already durably recorded in j.events for subscribe()'s replay. Blocking here would
+		// stall publish for every worker, since they all contend on j.mu.
+		select {
+		case sub <- evt:
+		default:
+		}
+	}
+}
+
+func (j *bulkJob) finish() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.done = true
+	for _, sub := range j.subs {
+		close(sub)
+	}
+}
+
+func (j *bulkJob) subscribe() (<-chan bulkBucketEvent, []bulkBucketEvent) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	replay := append([]bulkBucketEvent{}, j.events...)
+	if j.done {
+		return nil, replay
+	}
+	sub := make(chan bulkBucketEvent, 64)
+	j.subs = append(j.subs, sub)
+	return sub, replay
+}
+
+var (
+	bulkJobsMu sync.Mutex
+	bulkJobs   = map[string]*bulkJob{}
+)
+
+// newBulkJob registers a new job owned by owner (the creating session's AccessKeyID) under an
+// unguessable, crypto/rand-derived jobID, so a caller can't enumerate or guess another tenant's
+// job and watch its progress over the /ws/bulk/{jobID} websocket. It also sweeps out any
+// previously-registered jobs that have aged past bulkJobTTL.
+func newBulkJob(owner string) (string, *bulkJob) {
+	idBytes := make([]byte, 16)
+	jobID := fmt.Sprintf("%d", time.Now().UnixNano())
+	if _, err := rand.Read(idBytes); err == nil {
+		jobID = hex.EncodeToString(idBytes)
+	} else {
+		log.Println("error generating bulk job id, falling back to a timestamp:", err)
+	}
+	job := &bulkJob{owner: owner, createdAt: time.Now()}
+
+	bulkJobsMu.Lock()
+	bulkJobs[jobID] = job
+	sweepExpiredBulkJobsLocked()
+	bulkJobsMu.Unlock()
+
+	return jobID, job
+}
+
+// sweepExpiredBulkJobsLocked deletes jobs older than bulkJobTTL. Callers must hold bulkJobsMu.
+func sweepExpiredBulkJobsLocked() {
+	cutoff := time.Now().Add(-bulkJobTTL)
+	for jobID, job := range bulkJobs {
+		if job.createdAt.Before(cutoff) {
+			delete(bulkJobs, jobID)
+		}
+	}
+}
+
+// runBulkJob fans work out across workerCount goroutines, deriving one context per bucket from
+// ctx so cancelling the request context stops in-flight and not-yet-started work alike.
+func runBulkJob(ctx context.Context, job *bulkJob, buckets []string, workerCount int, work func(ctx context.Context, bucketName string) error) {
+	if workerCount <= 0 {
+		workerCount = defaultBulkWorkerCount
+	}
+	bucketCh := make(chan string)
+	var wg sync.WaitGroup
+	for i := 0; i < workerCount; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for bucketName := range bucketCh {
+				itemCtx, cancel := context.WithTimeout(ctx, time.Second*20)
+				err := work(itemCtx, bucketName)
+				cancel()
+				evt := bulkBucketEvent{Bucket: bucketName, Status: "done"}
+				if err != nil {
+					evt.Status = "error"
+					evt.Error = err.Error()
+				}
+				job.publish(evt)
+			}
+		}()
+	}
+	for _, bucketName := range buckets {
+		select {
+		case bucketCh <- bucketName:
+		case <-ctx.Done():
+		}
+	}
+	close(bucketCh)
+	wg.Wait()
+	job.finish()
+}
+
+func registerBulkBucketHandlers(api *operations.ConsoleAPI) {
+	// delete many buckets, optionally emptying them first
+	api.UserAPIBulkDeleteBucketsHandler = user_api.BulkDeleteBucketsHandlerFunc(func(params user_api.BulkDeleteBucketsParams, session *models.Principal) middleware.Responder {
+		resp, err := getBulkDeleteBucketsResponse(session, params.Body)
+		if err != nil {
+			return user_api.NewBulkDeleteBucketsDefault(500).WithPayload(&models.Error{Code: 500, Message: swag.String(err.Error())})
+		}
+		return user_api.NewBulkDeleteBucketsOK().WithPayload(resp)
+	})
+	// apply one access policy to many buckets
+	api.UserAPIBulkSetPolicyHandler = user_api.BulkSetPolicyHandlerFunc(func(params user_api.BulkSetPolicyParams, session *models.Principal) middleware.Responder {
+		resp, err := getBulkSetPolicyResponse(session, params.Body)
+		if err != nil {
+			return user_api.NewBulkSetPolicyDefault(500).WithPayload(&models.Error{Code: 500, Message: swag.String(err.Error())})
+		}
+		return user_api.NewBulkSetPolicyOK().WithPayload(resp)
+	})
+	// create many buckets sharing versioning/object-lock/region settings
+	api.UserAPIBulkMakeBucketsHandler = user_api.BulkMakeBucketsHandlerFunc(func(params user_api.BulkMakeBucketsParams, session *models.Principal) middleware.Responder {
+		resp, err := getBulkMakeBucketsResponse(session, params.Body)
+		if err != nil {
+			return user_api.NewBulkMakeBucketsDefault(500).WithPayload(&models.Error{Code: 500, Message: swag.String(err.Error())})
+		}
+		return user_api.NewBulkMakeBucketsOK().WithPayload(resp)
+	})
+}
+
+// emptyBucket removes every object (and version, if versioned) from bucketName in batches ahead
+// of a forced delete.
+func emptyBucket(ctx context.Context, client MinioClient, bucketName string) error {
+	objectsCh := client.listObjects(ctx, bucketName)
+	errorCh := client.removeObjects(ctx, bucketName, objectsCh)
+	for err := range errorCh {
+		if err.Err != nil {
+			return err.Err
+		}
+	}
+	return nil
+}
+
+// getBulkDeleteBucketsResponse deletes req.Buckets through a worker pool, optionally emptying
+// each bucket first when req.Force is set, and returns a jobID plus an inline summary.
+func getBulkDeleteBucketsResponse(session *models.Principal, req *models.BulkDeleteBucketsRequest) (*models.BulkOperationResponse, error) {
+	mClient, err := newMinioClient(session)
+	if err != nil {
+		log.Println("error creating MinIO Client:", err)
+		return nil, err
+	}
+	minioClient := minioClient{client: mClient}
+
+	jobID, job := newBulkJob(session.AccessKeyID)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		defer cancel()
+		runBulkJob(ctx, job, req.Buckets, int(req.Workers), func(itemCtx context.Context, bucketName string) error {
+			if req.Force {
+				if err := emptyBucket(itemCtx, minioClient, bucketName); err != nil {
+					return err
+				}
+			}
+			return minioClient.removeBucket(itemCtx, bucketName)
+		})
+	}()
+
+	return waitForSmallBulkJob(job, req.Buckets, jobID), nil
+}
+
+// getBulkSetPolicyResponse applies req.Access to every bucket in req.Buckets through a worker pool
+func getBulkSetPolicyResponse(session *models.Principal, req *models.BulkSetPolicyRequest) (*models.BulkOperationResponse, error) {
+	mClient, err := newMinioClient(session)
+	if err != nil {
+		log.Println("error creating MinIO Client:", err)
+		return nil, err
+	}
+	minioClient := minioClient{client: mClient}
+
+	jobID, job := newBulkJob(session.AccessKeyID)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		defer cancel()
+		runBulkJob(ctx, job, req.Buckets, int(req.Workers), func(itemCtx context.Context, bucketName string) error {
+			return setBucketAccessPolicy(itemCtx, minioClient, bucketName, req.Access)
+		})
+	}()
+
+	return waitForSmallBulkJob(job, req.Buckets, jobID), nil
+}
+
+// getBulkMakeBucketsResponse creates every bucket in req.Names sharing the same versioning,
+// object-lock and region settings through a worker pool
+func getBulkMakeBucketsResponse(session *models.Principal, req *models.BulkMakeBucketsRequest) (*models.BulkOperationResponse, error) {
+	mClient, err := newMinioClient(session)
+	if err != nil {
+		log.Println("error creating MinIO Client:", err)
+		return nil, err
+	}
+	minioClient := minioClient{client: mClient}
+
+	jobID, job := newBulkJob(session.AccessKeyID)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		defer cancel()
+		runBulkJob(ctx, job, req.Names, int(req.Workers), func(itemCtx context.Context, bucketName string) error {
+			if err := minioClient.makeBucketWithContext(itemCtx, bucketName, req.Region); err != nil {
+				return err
+			}
+			if req.ObjectLockEnabled {
+				if err := setBucketObjectLockConfig(itemCtx, mClient, bucketName, req.ObjectLock); err != nil {
+					return err
+				}
+			}
+			if req.VersioningEnabled {
+				return setBucketVersioning(itemCtx, mClient, bucketName, &models.SetBucketVersioningRequest{Enabled: true})
+			}
+			return nil
+		})
+	}()
+
+	return waitForSmallBulkJob(job, req.Names, jobID), nil
+}
+
+// waitForSmallBulkJob blocks for the job's events when the batch is small enough to answer
+// synchronously, returning a summary alongside the jobID; larger batches return immediately and
+// rely on the /ws/bulk/{jobID} endpoint for progress.
+func waitForSmallBulkJob(job *bulkJob, buckets []string, jobID string) *models.BulkOperationResponse {
+	const synchronousSummaryLimit = 25
+	resp := &models.BulkOperationResponse{JobID: jobID}
+	if len(buckets) > synchronousSummaryLimit {
+		return resp
+	}
+	sub, replay := job.subscribe()
+	results := map[string]bulkBucketEvent{}
+	for _, evt := range replay {
+		results[evt.Bucket] = evt
+	}
+	if sub != nil {
+		for evt := range sub {
+			results[evt.Bucket] = evt
+		}
+	}
+	for _, bucketName := range buckets {
+		evt := results[bucketName]
+		resp.Results = append(resp.Results, &models.BulkOperationResult{
+			Bucket: bucketName,
+			Status: evt.Status,
+			Error:  evt.Error,
+		})
+	}
+	return resp
+}
+
+// serveBulkJobProgress upgrades r to a websocket and streams {bucket, status, error} events for
+// jobID as they occur. It is mounted directly on the router at /ws/bulk/{jobID}, alongside the
+// console's other websocket endpoints, rather than through the generated operations.ConsoleAPI
+// handlers since those only model request/response JSON calls. The caller must have already
+// authenticated session and confirmed it owns jobID (see WrapHandler), since this function has
+// no other way to stop a caller who guesses or enumerates another tenant's jobID.
+func serveBulkJobProgress(w http.ResponseWriter, r *http.Request, jobID string, session *models.Principal) {
+	bulkJobsMu.Lock()
+	job, ok := bulkJobs[jobID]
+	bulkJobsMu.Unlock()
+	if !ok {
+		http.Error(w, "job not found", http.StatusNotFound)
+		return
+	}
+	if job.owner == "" || session == nil || job.owner != session.AccessKeyID {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+	conn, err := wsUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Println("error upgrading bulk job websocket:", err)
+		return
+	}
+	defer conn.Close()
+
+	sub, replay := job.subscribe()
+	for _, evt := range replay {
+		if err := conn.WriteJSON(evt); err != nil {
+			return
+		}
+	}
+	if sub == nil {
+		return
+	}
+	for evt := range sub {
+		if err := conn.WriteJSON(evt); err != nil {
+			return
+		}
+	}
+}
+
+var wsUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+}
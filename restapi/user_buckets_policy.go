@@ -0,0 +1,316 @@
+// This file is part of MinIO Console Server
+// Copyright (c) 2020 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package restapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/swag"
+	"github.com/minio/console/models"
+	"github.com/minio/console/restapi/operations"
+	"github.com/minio/console/restapi/operations/user_api"
+	"github.com/minio/minio-go/v7/pkg/policy"
+	minioIAMPolicy "github.com/minio/minio/pkg/iam/policy"
+)
+
+func registerBucketPolicyHandlers(api *operations.ConsoleAPI) {
+	// get the raw bucket policy document
+	api.UserAPIBucketGetPolicyHandler = user_api.BucketGetPolicyHandlerFunc(func(params user_api.BucketGetPolicyParams, session *models.Principal) middleware.Responder {
+		bucketPolicyResp, err := getBucketPolicyResponse(session, params.Name)
+		if err != nil {
+			return user_api.NewBucketGetPolicyDefault(500).WithPayload(&models.Error{Code: 500, Message: swag.String(err.Error())})
+		}
+		return user_api.NewBucketGetPolicyOK().WithPayload(bucketPolicyResp)
+	})
+	// replace the bucket policy document
+	api.UserAPIBucketPutPolicyHandler = user_api.BucketPutPolicyHandlerFunc(func(params user_api.BucketPutPolicyParams, session *models.Principal) middleware.Responder {
+		bucketPolicyResp, err := getBucketPutPolicyResponse(session, params.Name, params.Body)
+		if err != nil {
+			return user_api.NewBucketPutPolicyDefault(500).WithPayload(&models.Error{Code: 500, Message: swag.String(err.Error())})
+		}
+		return user_api.NewBucketPutPolicyOK().WithPayload(bucketPolicyResp)
+	})
+	// grant/restrict access to a single prefix inside the bucket
+	api.UserAPIBucketSetPrefixAccessHandler = user_api.BucketSetPrefixAccessHandlerFunc(func(params user_api.BucketSetPrefixAccessParams, session *models.Principal) middleware.Responder {
+		if err := getBucketSetPrefixAccessResponse(session, params.Name, params.Body); err != nil {
+			return user_api.NewBucketSetPrefixAccessDefault(500).WithPayload(&models.Error{Code: 500, Message: swag.String(err.Error())})
+		}
+		return user_api.NewBucketSetPrefixAccessNoContent()
+	})
+	// read the access currently granted to a single prefix inside the bucket
+	api.UserAPIBucketGetPrefixAccessHandler = user_api.BucketGetPrefixAccessHandlerFunc(func(params user_api.BucketGetPrefixAccessParams, session *models.Principal) middleware.Responder {
+		prefixAccessResp, err := getBucketGetPrefixAccessResponse(session, params.Name, params.ObjectPrefix)
+		if err != nil {
+			return user_api.NewBucketGetPrefixAccessDefault(500).WithPayload(&models.Error{Code: 500, Message: swag.String(err.Error())})
+		}
+		return user_api.NewBucketGetPrefixAccessOK().WithPayload(prefixAccessResp)
+	})
+}
+
+// getBucketPolicy fetches the raw policy document attached to bucketName, parsing it into the
+// structured models.BucketPolicy shape used by the UI's policy editor.
+func getBucketPolicy(ctx context.Context, client MinioClient, bucketName string) (*models.BucketPolicy, error) {
+	policyStr, err := client.getBucketPolicy(ctx, bucketName)
+	if err != nil {
+		return nil, err
+	}
+	if policyStr == "" {
+		return &models.BucketPolicy{Version: minioIAMPolicy.DefaultVersion, Statements: []*models.BucketPolicyStatement{}}, nil
+	}
+	return parseBucketPolicy(policyStr)
+}
+
+// parseBucketPolicy unmarshals a raw IAM policy document into the structured models.BucketPolicy
+// representation so the console can render and edit individual statements.
+func parseBucketPolicy(policyStr string) (*models.BucketPolicy, error) {
+	var p minioIAMPolicy.Policy
+	if err := json.Unmarshal([]byte(policyStr), &p); err != nil {
+		return nil, err
+	}
+	bucketPolicy := &models.BucketPolicy{Version: p.Version}
+	for _, statement := range p.Statements {
+		principalJSON, err := json.Marshal(statement.Principal)
+		if err != nil {
+			return nil, err
+		}
+		conditionsJSON, err := json.Marshal(statement.Conditions)
+		if err != nil {
+			return nil, err
+		}
+		bucketPolicy.Statements = append(bucketPolicy.Statements, &models.BucketPolicyStatement{
+			Effect:     string(statement.Effect),
+			Principal:  string(principalJSON),
+			Actions:    statement.Actions.ToSlice(),
+			Resources:  statement.Resources.ToSlice(),
+			Conditions: string(conditionsJSON),
+		})
+	}
+	return bucketPolicy, nil
+}
+
+// getBucketPolicyResponse calls getBucketPolicy() and returns the serialized output
+func getBucketPolicyResponse(session *models.Principal, bucketName string) (*models.BucketPolicy, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*20)
+	defer cancel()
+
+	mClient, err := newMinioClient(session)
+	if err != nil {
+		log.Println("error creating MinIO Client:", err)
+		return nil, err
+	}
+	// create a minioClient interface implementation
+	// defining the client to be used
+	minioClient := minioClient{client: mClient}
+
+	bucketPolicy, err := getBucketPolicy(ctx, minioClient, bucketName)
+	if err != nil {
+		log.Println("error getting bucket's policy:", err)
+		return nil, err
+	}
+	return bucketPolicy, nil
+}
+
+// bucketPolicyStatementToIAM converts a structured models.BucketPolicyStatement back into a
+// minioIAMPolicy.Statement, round-tripping Principal/Conditions through JSON since
+// parseBucketPolicy produced them by marshaling the equivalent minioIAMPolicy types the same way.
+func bucketPolicyStatementToIAM(statement *models.BucketPolicyStatement) (minioIAMPolicy.Statement, error) {
+	raw := map[string]json.RawMessage{
+		"Effect": mustMarshal(statement.Effect),
+		"Action": mustMarshal(statement.Actions),
+	}
+	if statement.Principal != "" {
+		raw["Principal"] = json.RawMessage(statement.Principal)
+	}
+	if len(statement.Resources) > 0 {
+		raw["Resource"] = mustMarshal(statement.Resources)
+	}
+	if statement.Conditions != "" {
+		raw["Condition"] = json.RawMessage(statement.Conditions)
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return minioIAMPolicy.Statement{}, err
+	}
+	var iamStatement minioIAMPolicy.Statement
+	if err := json.Unmarshal(data, &iamStatement); err != nil {
+		return minioIAMPolicy.Statement{}, err
+	}
+	return iamStatement, nil
+}
+
+// mustMarshal marshals v, which is always a plain string or []string and therefore never fails.
+func mustMarshal(v interface{}) json.RawMessage {
+	data, _ := json.Marshal(v)
+	return data
+}
+
+// setBucketPolicy validates and stores req as the bucket's full policy document. It accepts
+// either a raw JSON document (req.RawPolicy) or the structured statement form (req.Statements),
+// serializing the latter before validating it with minioIAMPolicy.
+func setBucketPolicy(ctx context.Context, client MinioClient, bucketName string, req *models.SetBucketPolicyRequest) error {
+	if strings.TrimSpace(bucketName) == "" {
+		return fmt.Errorf("error: bucket name not present")
+	}
+	policyJSON := req.RawPolicy
+	if policyJSON == "" {
+		if req.Policy == nil {
+			return fmt.Errorf("error: policy not present")
+		}
+		var statements []minioIAMPolicy.Statement
+		for _, statement := range req.Policy.Statements {
+			iamStatement, err := bucketPolicyStatementToIAM(statement)
+			if err != nil {
+				return fmt.Errorf("error: invalid policy statement: %v", err)
+			}
+			statements = append(statements, iamStatement)
+		}
+		bucketPolicy := minioIAMPolicy.Policy{Version: minioIAMPolicy.DefaultVersion, Statements: statements}
+		marshaled, err := json.Marshal(bucketPolicy)
+		if err != nil {
+			return err
+		}
+		policyJSON = string(marshaled)
+	}
+	if err := minioIAMPolicy.ParseConfig(strings.NewReader(policyJSON)); err != nil {
+		return fmt.Errorf("error: invalid bucket policy: %v", err)
+	}
+	return client.setBucketPolicyWithContext(ctx, bucketName, policyJSON)
+}
+
+// getBucketPutPolicyResponse calls setBucketPolicy() and returns the resulting policy document
+func getBucketPutPolicyResponse(session *models.Principal, bucketName string, req *models.SetBucketPolicyRequest) (*models.BucketPolicy, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*20)
+	defer cancel()
+
+	mClient, err := newMinioClient(session)
+	if err != nil {
+		log.Println("error creating MinIO Client:", err)
+		return nil, err
+	}
+	// create a minioClient interface implementation
+	// defining the client to be used
+	minioClient := minioClient{client: mClient}
+
+	if err := setBucketPolicy(ctx, minioClient, bucketName, req); err != nil {
+		log.Println("error setting bucket policy:", err)
+		return nil, err
+	}
+	bucketPolicy, err := getBucketPolicy(ctx, minioClient, bucketName)
+	if err != nil {
+		log.Println("error getting bucket's policy:", err)
+		return nil, err
+	}
+	return bucketPolicy, nil
+}
+
+// setBucketPrefixAccess grants or restricts access for a single object prefix inside bucketName,
+// mirroring the older minio-go GetBucketPolicy(bucketName, objectPrefix) shape so existing
+// statements scoped to other prefixes are left untouched.
+func setBucketPrefixAccess(ctx context.Context, client MinioClient, bucketName string, req *models.SetBucketPrefixAccessRequest) error {
+	if strings.TrimSpace(bucketName) == "" {
+		return fmt.Errorf("error: bucket name not present")
+	}
+	if strings.TrimSpace(req.ObjectPrefix) == "" {
+		return fmt.Errorf("error: object prefix not present")
+	}
+	policyStr, err := client.getBucketPolicy(ctx, bucketName)
+	if err != nil {
+		return err
+	}
+	var bucketAccessPolicy policy.BucketAccessPolicy
+	if policyStr != "" {
+		if err := json.Unmarshal([]byte(policyStr), &bucketAccessPolicy); err != nil {
+			return err
+		}
+	}
+	if bucketAccessPolicy.Version == "" {
+		bucketAccessPolicy.Version = minioIAMPolicy.DefaultVersion
+	}
+	bucketAccessPolicy.Statements = policy.SetPolicy(bucketAccessPolicy.Statements,
+		consoleAccess2policyAccess(req.Access), bucketName, req.ObjectPrefix)
+
+	if len(bucketAccessPolicy.Statements) == 0 {
+		return client.setBucketPolicyWithContext(ctx, bucketName, "")
+	}
+	policyJSON, err := json.Marshal(bucketAccessPolicy)
+	if err != nil {
+		return err
+	}
+	return client.setBucketPolicyWithContext(ctx, bucketName, string(policyJSON))
+}
+
+// getBucketSetPrefixAccessResponse calls setBucketPrefixAccess() to scope an access policy to a prefix
+func getBucketSetPrefixAccessResponse(session *models.Principal, bucketName string, req *models.SetBucketPrefixAccessRequest) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*20)
+	defer cancel()
+
+	mClient, err := newMinioClient(session)
+	if err != nil {
+		log.Println("error creating MinIO Client:", err)
+		return err
+	}
+	// create a minioClient interface implementation
+	// defining the client to be used
+	minioClient := minioClient{client: mClient}
+
+	return setBucketPrefixAccess(ctx, minioClient, bucketName, req)
+}
+
+// getBucketPrefixAccess reads back the access currently granted to objectPrefix inside bucketName
+func getBucketPrefixAccess(ctx context.Context, client MinioClient, bucketName, objectPrefix string) (*models.PrefixAccess, error) {
+	policyStr, err := client.getBucketPolicy(ctx, bucketName)
+	if err != nil {
+		return nil, err
+	}
+	var policyAccess policy.BucketPolicy
+	if policyStr == "" {
+		policyAccess = policy.BucketPolicyNone
+	} else {
+		var p policy.BucketAccessPolicy
+		if err := json.Unmarshal([]byte(policyStr), &p); err != nil {
+			return nil, err
+		}
+		policyAccess = policy.GetPolicy(p.Statements, bucketName, objectPrefix)
+	}
+	return &models.PrefixAccess{
+		ObjectPrefix: objectPrefix,
+		Access:       policyAccess2consoleAccess(policyAccess),
+	}, nil
+}
+
+// getBucketGetPrefixAccessResponse calls getBucketPrefixAccess() and returns the serialized output
+func getBucketGetPrefixAccessResponse(session *models.Principal, bucketName, objectPrefix string) (*models.PrefixAccess, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*20)
+	defer cancel()
+
+	mClient, err := newMinioClient(session)
+	if err != nil {
+		log.Println("error creating MinIO Client:", err)
+		return nil, err
+	}
+	// create a minioClient interface implementation
+	// defining the client to be used
+	minioClient := minioClient{client: mClient}
+
+	return getBucketPrefixAccess(ctx, minioClient, bucketName, objectPrefix)
+}
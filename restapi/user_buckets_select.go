@@ -0,0 +1,171 @@
+// This file is part of MinIO Console Server
+// Copyright (c) 2020 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package restapi
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/go-openapi/runtime"
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/swag"
+	"github.com/minio/console/models"
+	"github.com/minio/console/restapi/operations"
+	"github.com/minio/console/restapi/operations/user_api"
+	minio "github.com/minio/minio-go/v7"
+)
+
+// selectPreviewRowCap and selectPreviewByteCap bound how much of an object a single preview
+// request may stream back, keeping the console responsive when a user previews a multi-GB object.
+const (
+	selectPreviewRowCap  = 1000
+	selectPreviewByteCap = 10 << 20 // 10MiB
+)
+
+func registerBucketSelectHandlers(api *operations.ConsoleAPI) {
+	// preview an object's contents via S3 Select, streamed as newline-delimited JSON
+	api.UserAPIBucketSelectObjectContentHandler = user_api.BucketSelectObjectContentHandlerFunc(func(params user_api.BucketSelectObjectContentParams, session *models.Principal) middleware.Responder {
+		return middleware.ResponderFunc(func(w http.ResponseWriter, producer runtime.Producer) {
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			w.Header().Set("Transfer-Encoding", "chunked")
+			if err := streamSelectObjectContent(w, session, params.Body); err != nil {
+				log.Println("error streaming select object content:", err)
+			}
+		})
+	})
+}
+
+// writeSelectError writes a models.Error payload as the response body. statusHeaderSent reports
+// whether the 200 status line for the ndjson stream has already gone out: if it has, the caller
+// is mid-stream and can only append an error frame, not change the status code.
+func writeSelectError(w http.ResponseWriter, statusHeaderSent bool, err error) error {
+	if !statusHeaderSent {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		return json.NewEncoder(w).Encode(&models.Error{Code: 500, Message: swag.String(err.Error())})
+	}
+	return json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
+
+// buildSelectInputSerialization translates the UI-facing models.SelectObjectInputSerialization
+// into the minio-go SelectObjectInputSerialization understood by SelectObjectContent.
+func buildSelectInputSerialization(in *models.SelectObjectInputSerialization) minio.SelectObjectInputSerialization {
+	s := minio.SelectObjectInputSerialization{
+		CompressionType: minio.SelectCompressionType(in.CompressionType),
+	}
+	switch in.Format {
+	case models.SelectObjectInputSerializationFormatCSV:
+		s.CSV = &minio.CSVInputOptions{FileHeaderInfo: minio.CSVFileHeaderInfoUse}
+	case models.SelectObjectInputSerializationFormatJSON:
+		s.JSON = &minio.JSONInputOptions{Type: minio.JSONLinesType}
+	case models.SelectObjectInputSerializationFormatParquet:
+		s.Parquet = &minio.ParquetInputOptions{}
+	}
+	return s
+}
+
+// buildSelectOutputSerialization translates the UI-facing models.SelectObjectOutputSerialization
+// into the minio-go SelectObjectOutputSerialization understood by SelectObjectContent.
+func buildSelectOutputSerialization(out *models.SelectObjectOutputSerialization) minio.SelectObjectOutputSerialization {
+	s := minio.SelectObjectOutputSerialization{}
+	switch out.Format {
+	case models.SelectObjectOutputSerializationFormatCSV:
+		s.CSV = &minio.CSVOutputOptions{}
+	default:
+		s.JSON = &minio.JSONOutputOptions{}
+	}
+	return s
+}
+
+// streamSelectObjectContent runs req against bucket/object via S3 Select and writes each result
+// row, then a final Stats frame, as its own newline-delimited JSON message to w. It enforces
+// selectPreviewRowCap/selectPreviewByteCap so a UI preview cannot pull an entire large object.
+//
+// Known gap: this only ever emits a Stats frame, never a Progress one. minio-go's SelectResults
+// type discards the individual Progress events S3 Select emits while a query is still running and
+// only exposes the final Stats once streaming completes, so there is currently no data to surface
+// mid-stream progress from. A UI that wants a progress indicator during a large preview cannot get
+// one from this endpoint today; that would require either a minio-go change to expose Progress
+// events or parsing the select response frames ourselves instead of going through SelectResults.
+func streamSelectObjectContent(w http.ResponseWriter, session *models.Principal, req *models.SelectObjectContentRequest) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*2)
+	defer cancel()
+
+	mClient, err := newMinioClient(session)
+	if err != nil {
+		log.Println("error creating MinIO Client:", err)
+		return writeSelectError(w, false, err)
+	}
+
+	opts := minio.SelectObjectOptions{
+		Expression:          req.Expression,
+		ExpressionType:      minio.QueryExpressionTypeSQL,
+		InputSerialization:  buildSelectInputSerialization(req.InputSerialization),
+		OutputSerialization: buildSelectOutputSerialization(req.OutputSerialization),
+	}
+	result, err := mClient.SelectObjectContent(ctx, req.Bucket, req.Object, opts)
+	if err != nil {
+		return writeSelectError(w, false, err)
+	}
+	defer result.Close()
+
+	// From here on the 200 status line is committed to the wire: any later error can only be
+	// reported as an error frame inside the ndjson stream, not a different HTTP status.
+	w.WriteHeader(http.StatusOK)
+	encoder := json.NewEncoder(w)
+	flusher, _ := w.(http.Flusher)
+
+	var rows, bytesWritten int
+	lineBuf := make([]byte, 0, 4096)
+	readBuf := make([]byte, 32*1024)
+	for {
+		n, readErr := result.Read(readBuf)
+		for _, b := range readBuf[:n] {
+			bytesWritten++
+			if b == '\n' {
+				if err := encoder.Encode(map[string]string{"record": string(lineBuf)}); err != nil {
+					return err
+				}
+				if flusher != nil {
+					flusher.Flush()
+				}
+				rows++
+				lineBuf = lineBuf[:0]
+				continue
+			}
+			lineBuf = append(lineBuf, b)
+		}
+		if rows >= selectPreviewRowCap || bytesWritten >= selectPreviewByteCap {
+			return encoder.Encode(map[string]string{"truncated": "row or byte cap reached"})
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return writeSelectError(w, true, readErr)
+		}
+	}
+
+	if stats := result.Stats(); stats != nil {
+		return encoder.Encode(map[string]interface{}{"stats": stats})
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+// This file is part of MinIO Console Server
+// Copyright (c) 2020 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package restapi
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/minio/console/models"
+)
+
+// accountUsageInfoCacheTTL bounds how stale the cached per-bucket usage breakdown can be. It is
+// short enough that a bucket just created or deleted shows up within a page or two, while still
+// sparing the admin API from a round-trip on every paginated ListBuckets request.
+const accountUsageInfoCacheTTL = 10 * time.Second
+
+type accountUsageInfoCacheEntry struct {
+	buckets    []*models.Bucket
+	totalUsage int64
+	expiresAt  time.Time
+}
+
+var (
+	accountUsageInfoCacheMu sync.Mutex
+	accountUsageInfoCache   = map[string]accountUsageInfoCacheEntry{}
+)
+
+// getCachedAccountUsageInfo returns the per-bucket usage breakdown and total account usage for
+// session, serving it from accountUsageInfoCache when a fresh-enough entry exists so paging
+// through the bucket list does not re-hit the admin API on every request.
+func getCachedAccountUsageInfo(ctx context.Context, client MinioAdmin, session *models.Principal) ([]*models.Bucket, int64, error) {
+	cacheKey := session.AccessKeyID
+
+	accountUsageInfoCacheMu.Lock()
+	if entry, ok := accountUsageInfoCache[cacheKey]; ok && time.Now().Before(entry.expiresAt) {
+		accountUsageInfoCacheMu.Unlock()
+		return entry.buckets, entry.totalUsage, nil
+	}
+	accountUsageInfoCacheMu.Unlock()
+
+	buckets, err := getaAcountUsageInfo(ctx, client)
+	if err != nil {
+		return nil, 0, err
+	}
+	var totalUsage int64
+	for _, bucket := range buckets {
+		totalUsage += bucket.Size
+	}
+
+	accountUsageInfoCacheMu.Lock()
+	accountUsageInfoCache[cacheKey] = accountUsageInfoCacheEntry{
+		buckets:    buckets,
+		totalUsage: totalUsage,
+		expiresAt:  time.Now().Add(accountUsageInfoCacheTTL),
+	}
+	sweepExpiredAccountUsageInfoLocked()
+	accountUsageInfoCacheMu.Unlock()
+
+	return buckets, totalUsage, nil
+}
+
+// sweepExpiredAccountUsageInfoLocked drops entries past their TTL so a console process serving
+// many distinct sessions (access keys) over its lifetime does not accumulate one stale cache
+// entry per session forever; otherwise an entry only ever gets replaced, never removed, when its
+// access key happens to page through the bucket list again. Callers must hold accountUsageInfoCacheMu.
+func sweepExpiredAccountUsageInfoLocked() {
+	now := time.Now()
+	for cacheKey, entry := range accountUsageInfoCache {
+		if now.After(entry.expiresAt) {
+			delete(accountUsageInfoCache, cacheKey)
+		}
+	}
+}